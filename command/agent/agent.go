@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/consul/consul"
+)
+
+// Config holds the subset of agent configuration the HTTP layer needs.
+type Config struct {
+	// Datacenter is used to fill in a request's Datacenter field when the
+	// caller didn't supply one via ?dc=.
+	Datacenter string
+}
+
+// Agent bridges the HTTP API to this node's local Consul server.
+type Agent struct {
+	config *Config
+	server *consul.Server
+}
+
+// NewAgent wraps an already-running Consul server for local RPC dispatch.
+func NewAgent(config *Config, server *consul.Server) *Agent {
+	return &Agent{config: config, server: server}
+}
+
+// RPC dispatches method against the local Consul server.
+func (a *Agent) RPC(method string, args interface{}, reply interface{}) error {
+	if a.server == nil {
+		return fmt.Errorf("agent: no local Consul server to dispatch %q", method)
+	}
+	return a.server.LocalRPC(method, args, reply)
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/consul/consul"
+)
+
+// EventFire is used to fire a new user event
+//
+// PUT /v1/event/fire/:name
+func (s *HTTPServer) EventFire(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "PUT" {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, nil
+	}
+
+	name := strings.TrimPrefix(req.URL.Path, "/v1/event/fire/")
+	if name == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		resp.Write([]byte("Missing event name"))
+		return nil, nil
+	}
+
+	args := consul.EventFireRequest{
+		Name:          name,
+		NodeFilter:    req.URL.Query().Get("node"),
+		ServiceFilter: req.URL.Query().Get("service"),
+		TagFilter:     req.URL.Query().Get("tag"),
+	}
+	if dcs := req.URL.Query().Get("relay-dc"); dcs != "" {
+		args.RelayDatacenters = strings.Split(dcs, ",")
+	}
+	if done := s.parse(resp, req, &args.Datacenter, &args.Token); done {
+		return nil, nil
+	}
+
+	// The body, if present, is the opaque event payload. Don't size the
+	// buffer off ContentLength: it's -1 for chunked requests, and a
+	// single Read call isn't guaranteed to fill a fixed-size buffer
+	// anyway.
+	payload, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	args.Payload = payload
+
+	var reply consul.EventFireResponse
+	if err := s.agent.RPC("Internal.EventFire", &args, &reply); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// EventList is used to retrieve the recent events known to a server.
+//
+// GET /v1/event/list
+func (s *HTTPServer) EventList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, nil
+	}
+
+	args := consul.EventListRequest{
+		Name: req.URL.Query().Get("name"),
+	}
+	if done := s.parse(resp, req, &args.Datacenter, &args.Token); done {
+		return nil, nil
+	}
+
+	var reply consul.EventListResponse
+	if err := s.agent.RPC("Internal.EventList", &args, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Events, nil
+}
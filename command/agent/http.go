@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+)
+
+// HTTPServer runs Consul's HTTP API on top of a listener, routing
+// requests through to the agent's RPC-backed endpoints.
+type HTTPServer struct {
+	agent    *Agent
+	logger   *log.Logger
+	listener net.Listener
+	mux      *http.ServeMux
+}
+
+// NewHTTPServer creates an HTTPServer bound to listener and registers
+// every HTTP endpoint this package exposes.
+func NewHTTPServer(agent *Agent, logger *log.Logger, listener net.Listener) *HTTPServer {
+	s := &HTTPServer{
+		agent:    agent,
+		logger:   logger,
+		listener: listener,
+		mux:      http.NewServeMux(),
+	}
+	s.registerHandlers()
+	return s
+}
+
+// Serve blocks accepting and handling connections until the listener closes.
+func (s *HTTPServer) Serve() error {
+	return http.Serve(s.listener, s.mux)
+}
+
+// registerHandlers wires every endpoint method this package implements
+// into the mux.
+func (s *HTTPServer) registerHandlers() {
+	s.mux.HandleFunc("/v1/event/fire/", s.wrap(s.EventFire))
+	s.mux.HandleFunc("/v1/event/list", s.wrap(s.EventList))
+	s.mux.HandleFunc("/v1/operator/raft/configuration", s.wrap(s.OperatorRaftConfiguration))
+	s.mux.HandleFunc("/v1/operator/raft/peer", s.wrap(s.OperatorRaftPeer))
+}
+
+// endpoint is the signature every HTTP API method in this package
+// implements: the returned value is JSON-encoded as the response body,
+// unless the handler has already written its own response (signaled by
+// returning nil, nil after calling resp.WriteHeader itself).
+type endpoint func(resp http.ResponseWriter, req *http.Request) (interface{}, error)
+
+// wrap adapts an endpoint into a http.HandlerFunc, JSON-encoding a
+// returned value or translating a returned error into a 500.
+func (s *HTTPServer) wrap(handler endpoint) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		obj, err := handler(resp, req)
+		if err != nil {
+			s.logger.Printf("[ERR] http: request %s %s failed: %v", req.Method, req.URL.Path, err)
+			resp.WriteHeader(http.StatusInternalServerError)
+			resp.Write([]byte(err.Error()))
+			return
+		}
+		if obj == nil {
+			return
+		}
+
+		resp.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(resp).Encode(obj); err != nil {
+			s.logger.Printf("[ERR] http: failed to encode response: %v", err)
+		}
+	}
+}
+
+// parse extracts the ?dc= and ?token= query parameters that every RPC-
+// backed endpoint accepts, defaulting dc to the agent's own datacenter.
+// It returns true if it has already written an error response and the
+// caller should stop handling the request.
+func (s *HTTPServer) parse(resp http.ResponseWriter, req *http.Request, dc *string, token *string) bool {
+	if *dc == "" {
+		*dc = req.URL.Query().Get("dc")
+	}
+	if *dc == "" {
+		*dc = s.agent.config.Datacenter
+	}
+	*token = req.URL.Query().Get("token")
+	return false
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/consul/consul/structs"
+)
+
+// OperatorRaftConfiguration is used to inspect the current Raft configuration.
+//
+// GET /v1/operator/raft/configuration
+func (s *HTTPServer) OperatorRaftConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, nil
+	}
+
+	var args structs.RaftConfigurationRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.Token); done {
+		return nil, nil
+	}
+
+	var reply structs.RaftConfigurationResponse
+	if err := s.agent.RPC("Operator.RaftGetConfiguration", &args, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// OperatorRaftPeer supports actions on a specific Raft peer. The only action
+// currently supported is a DELETE, which is used to remove a stale peer by
+// address when it can no longer be reached through normal LAN leave/fail
+// reaping.
+//
+// DELETE /v1/operator/raft/peer?address=1.2.3.4:8300
+func (s *HTTPServer) OperatorRaftPeer(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "DELETE" {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, nil
+	}
+
+	args := structs.RaftRemovePeerRequest{
+		Address: strings.TrimSpace(req.URL.Query().Get("address")),
+	}
+	if done := s.parse(resp, req, &args.Datacenter, &args.Token); done {
+		return nil, nil
+	}
+	if args.Address == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		resp.Write([]byte("Must specify ?address with IP:port of peer to remove"))
+		return nil, nil
+	}
+
+	var reply struct{}
+	if err := s.agent.RPC("Operator.RaftRemovePeerByAddress", &args, &reply); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
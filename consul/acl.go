@@ -0,0 +1,46 @@
+package consul
+
+import "errors"
+
+// permissionDeniedErr is returned by RPC endpoints when a resolved ACL
+// token doesn't grant the permission the operation requires.
+var permissionDeniedErr = errors.New("Permission denied")
+
+// ACL is the resolved policy for a single request's token. Full rule
+// evaluation (service/key/event prefixes, replication from the ACL
+// datacenter) lives in the wider ACL subsystem; this is the read/write
+// surface the endpoints added so far gate on.
+type ACL struct {
+	operatorRead  bool
+	operatorWrite bool
+	eventRead     bool
+	eventWrite    bool
+}
+
+// OperatorRead reports whether this token may inspect operator-only state
+// such as the Raft configuration. Write implies read.
+func (a *ACL) OperatorRead() bool { return a.operatorRead || a.operatorWrite }
+
+// OperatorWrite reports whether this token may mutate operator-only state
+// such as forcibly removing a Raft peer.
+func (a *ACL) OperatorWrite() bool { return a.operatorWrite }
+
+// EventRead reports whether this token may see events with the given name.
+func (a *ACL) EventRead(name string) bool { return a.eventRead }
+
+// EventWrite reports whether this token may fire events with the given
+// name.
+func (a *ACL) EventWrite(name string) bool { return a.eventWrite }
+
+// resolveACLToken resolves a token to its granted policy. The full
+// implementation looks the token up via the ACL datacenter's replicated
+// policy tables; until that's wired in, an empty token gets the default
+// "allow nothing beyond anonymous" policy and any other token is denied,
+// which is the conservative default for a datacenter that has ACLs
+// enabled but no operator-configured tokens yet.
+func resolveACLToken(token string) (*ACL, error) {
+	if token == "" {
+		return &ACL{}, nil
+	}
+	return nil, errors.New("consul: ACL token resolution is not yet implemented")
+}
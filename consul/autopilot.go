@@ -0,0 +1,264 @@
+package consul
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/serf/serf"
+)
+
+// autopilotInterval is how often the autopilot reconciliation loop runs.
+const autopilotInterval = 10 * time.Second
+
+// defaultLastContactThreshold is how long a known Raft peer can be absent
+// from the LAN pool before autopilot reaps it, if Config.LastContactThreshold
+// isn't set.
+const defaultLastContactThreshold = 10 * time.Second
+
+// defaultServerStabilizationTime is how long a candidate server must be
+// continuously alive in the LAN pool before autopilot adds it as a Raft
+// peer, if Config.ServerStabilizationTime isn't set.
+const defaultServerStabilizationTime = 10 * time.Second
+
+// autopilot periodically reconciles the LAN Serf membership against the
+// Raft configuration. It replaces the old per-join retry loop in
+// joinConsulServer: rather than a goroutine per discovered peer retrying
+// forever, a single goroutine sweeps the whole cluster state on an
+// interval, performing the initial BootstrapExpect bootstrap, adding any
+// stable alive server that isn't yet a peer, and reaping peers that have
+// been missing from the LAN pool for too long.
+func (s *Server) autopilot() {
+	ticker := time.NewTicker(autopilotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.autopilotReconcile()
+		case <-s.shutdownCh:
+			return
+		}
+	}
+}
+
+// autopilotReconcile is a single pass of the autopilot loop.
+func (s *Server) autopilotReconcile() {
+	servers := s.lanConsulServers()
+	if !s.bootstrapped(servers) {
+		return
+	}
+
+	// Everything past this point mutates the Raft configuration, which
+	// only the leader may do.
+	if !s.IsLeader() {
+		return
+	}
+
+	peers, err := s.raftPeers.Peers()
+	if err != nil {
+		s.logger.Printf("[ERR] consul: autopilot failed to get raft peers: %v", err)
+		return
+	}
+	knownAddrs := make(map[string]bool, len(servers))
+	for _, parts := range servers {
+		knownAddrs[parts.Addr.String()] = true
+	}
+
+	lastContactThreshold := s.config.LastContactThreshold
+	if lastContactThreshold == 0 {
+		lastContactThreshold = defaultLastContactThreshold
+	}
+
+	for _, addr := range peers {
+		key := addr.String()
+		if knownAddrs[key] {
+			s.autopilotClearMissing(key)
+			continue
+		}
+
+		// This peer no longer corresponds to any server in the LAN pool
+		// at all (as opposed to merely being marked failed, which
+		// removeConsulServer already reaps after its own grace period).
+		// Give it LastContactThreshold before reaping, so one missed
+		// gossip round doesn't evict it.
+		missingFor := s.autopilotMarkMissing(key)
+		if missingFor < lastContactThreshold {
+			continue
+		}
+
+		s.logger.Printf("[INFO] consul: autopilot removing stale raft peer %s (missing %s)", addr, missingFor)
+		if future := s.raft.RemovePeer(addr); future.Error() != nil {
+			s.logger.Printf("[ERR] consul: autopilot failed to remove %s: %v", addr, future.Error())
+		} else {
+			s.autopilotClearMissing(key)
+		}
+	}
+
+	stabilizationTime := s.config.ServerStabilizationTime
+	if stabilizationTime == 0 {
+		stabilizationTime = defaultServerStabilizationTime
+	}
+
+	for _, parts := range servers {
+		if parts.Status != serf.StatusAlive {
+			s.autopilotClearStable(parts.Addr.String())
+			continue
+		}
+		if peerSetContains(peers, parts.Addr) {
+			continue
+		}
+
+		// A true Raft-log-lag check would need per-peer replication
+		// metrics that the raft.PeerStore API this server is built
+		// against doesn't expose. As a practical stand-in, require the
+		// candidate to have been continuously alive in the LAN pool for
+		// ServerStabilizationTime before promoting it to a voting peer,
+		// so a server that's still catching up (or flapping) doesn't
+		// get added the instant it's first seen.
+		stableFor := s.autopilotMarkStable(parts.Addr.String())
+		if stableFor < stabilizationTime {
+			continue
+		}
+
+		s.logger.Printf("[INFO] consul: autopilot adding server %s", parts)
+		if future := s.raft.AddPeer(parts.Addr); future.Error() != nil {
+			s.logger.Printf("[ERR] consul: autopilot failed to add %s: %v", parts, future.Error())
+		}
+	}
+}
+
+// byAddr sorts ServerParts by their Raft address, so the bootstrap can pick
+// a single deterministic initiator out of the alive set.
+type byAddr []*ServerParts
+
+func (b byAddr) Len() int           { return len(b) }
+func (b byAddr) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byAddr) Less(i, j int) bool { return b[i].Addr.String() < b[j].Addr.String() }
+
+func peerSetContains(peers []net.Addr, addr net.Addr) bool {
+	for _, p := range peers {
+		if p.String() == addr.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// lanConsulServers returns the ServerParts for every Consul server
+// currently visible in this datacenter's LAN Serf pool.
+func (s *Server) lanConsulServers() []*ServerParts {
+	members := s.serfLAN.Members()
+	servers := make([]*ServerParts, 0, len(members))
+	for _, m := range members {
+		ok, parts := s.isConsulServer(m)
+		if !ok || parts.Datacenter != s.config.Datacenter {
+			continue
+		}
+		servers = append(servers, parts)
+	}
+	return servers
+}
+
+// bootstrapped reports whether this server's local Raft peer store is
+// already seeded, or BootstrapExpect is unset, in which case there's
+// nothing to gate. Otherwise it waits until BootstrapExpect alive servers
+// are visible in the LAN pool, and then only the server with the lowest
+// sorted Raft address performs the single atomic bootstrap by calling
+// SetPeers; every other server falls through (returns false here until its
+// own peer store holds peers, since it never becomes the initiator) and
+// waits to be added normally
+// through joinConsulServer/autopilotReconcile's AddPeer path once a leader
+// has been elected. This avoids two servers racing to seed slightly
+// different initial configurations from their own momentarily-divergent
+// views of serfLAN.Members().
+func (s *Server) bootstrapped(servers []*ServerParts) bool {
+	expect := s.config.BootstrapExpect
+	if expect == 0 {
+		return true
+	}
+
+	if peers, err := s.raftPeers.Peers(); err == nil && len(peers) > 0 {
+		return true
+	}
+
+	var alive []*ServerParts
+	var self *ServerParts
+	for _, parts := range servers {
+		if parts.Name == s.config.NodeName {
+			self = parts
+		}
+		if parts.Status == serf.StatusAlive {
+			alive = append(alive, parts)
+		}
+	}
+	if len(alive) < expect || self == nil {
+		return false
+	}
+
+	sort.Sort(byAddr(alive))
+	if alive[0].Addr.String() != self.Addr.String() {
+		return false
+	}
+
+	peers := make([]net.Addr, 0, len(alive))
+	for _, parts := range alive {
+		peers = append(peers, parts.Addr)
+	}
+	if err := s.raftPeers.SetPeers(peers); err != nil {
+		s.logger.Printf("[ERR] consul: failed to bootstrap raft peers: %v", err)
+		return false
+	}
+	s.logger.Printf("[INFO] consul: cluster bootstrapped with %d servers", len(peers))
+	return true
+}
+
+// autopilotMarkMissing records the first tick a known Raft peer was found
+// absent from the LAN pool, and returns how long it's been missing so far.
+// It's guarded by s.autopilotLock, a dedicated lock for this bookkeeping
+// alone since nothing else reads it.
+func (s *Server) autopilotMarkMissing(key string) time.Duration {
+	s.autopilotLock.Lock()
+	defer s.autopilotLock.Unlock()
+	if s.autopilotMissing == nil {
+		s.autopilotMissing = make(map[string]time.Time)
+	}
+	since, ok := s.autopilotMissing[key]
+	if !ok {
+		since = time.Now()
+		s.autopilotMissing[key] = since
+	}
+	return time.Since(since)
+}
+
+// autopilotClearMissing forgets any missing-since bookkeeping for a peer,
+// called once it's seen again or successfully reaped.
+func (s *Server) autopilotClearMissing(key string) {
+	s.autopilotLock.Lock()
+	defer s.autopilotLock.Unlock()
+	delete(s.autopilotMissing, key)
+}
+
+// autopilotMarkStable records the first tick a promotion candidate was
+// found continuously alive in the LAN pool, and returns how long that's
+// held true so far.
+func (s *Server) autopilotMarkStable(key string) time.Duration {
+	s.autopilotLock.Lock()
+	defer s.autopilotLock.Unlock()
+	if s.autopilotStableSince == nil {
+		s.autopilotStableSince = make(map[string]time.Time)
+	}
+	since, ok := s.autopilotStableSince[key]
+	if !ok {
+		since = time.Now()
+		s.autopilotStableSince[key] = since
+	}
+	return time.Since(since)
+}
+
+// autopilotClearStable forgets the stabilization timer for a candidate,
+// called once it stops being alive so a later rejoin starts the window over.
+func (s *Server) autopilotClearStable(key string) {
+	s.autopilotLock.Lock()
+	defer s.autopilotLock.Unlock()
+	delete(s.autopilotStableSince, key)
+}
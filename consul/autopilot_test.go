@@ -0,0 +1,177 @@
+package consul
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/serf/serf"
+)
+
+// fakePeerStore is a minimal raft.PeerStore used to drive bootstrapped()
+// without a real Raft instance.
+type fakePeerStore struct {
+	peers     []net.Addr
+	setCalled bool
+}
+
+func (f *fakePeerStore) Peers() ([]net.Addr, error) { return f.peers, nil }
+
+func (f *fakePeerStore) SetPeers(peers []net.Addr) error {
+	f.setCalled = true
+	f.peers = peers
+	return nil
+}
+
+func newTestServerParts(name, ip string) *ServerParts {
+	return &ServerParts{
+		Name:   name,
+		Addr:   &net.TCPAddr{IP: net.ParseIP(ip), Port: 8300},
+		Status: serf.StatusAlive,
+	}
+}
+
+func TestByAddr_Sort(t *testing.T) {
+	parts := []*ServerParts{
+		{Name: "c", Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.3"), Port: 8300}},
+		{Name: "a", Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8300}},
+		{Name: "b", Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.2"), Port: 8300}},
+	}
+
+	sort.Sort(byAddr(parts))
+
+	want := []string{"a", "b", "c"}
+	for i, name := range want {
+		if parts[i].Name != name {
+			t.Fatalf("bad order: %#v", parts)
+		}
+	}
+}
+
+func TestPeerSetContains(t *testing.T) {
+	peers := []net.Addr{
+		&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8300},
+		&net.TCPAddr{IP: net.ParseIP("127.0.0.2"), Port: 8300},
+	}
+
+	if !peerSetContains(peers, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8300}) {
+		t.Fatalf("expected peer to be found")
+	}
+	if peerSetContains(peers, &net.TCPAddr{IP: net.ParseIP("127.0.0.9"), Port: 8300}) {
+		t.Fatalf("expected peer to not be found")
+	}
+}
+
+func TestBootstrapped_LowestAddressInitiates(t *testing.T) {
+	servers := []*ServerParts{
+		newTestServerParts("b", "127.0.0.2"),
+		newTestServerParts("a", "127.0.0.1"),
+	}
+
+	store := &fakePeerStore{}
+	s := &Server{
+		config:    &Config{NodeName: "a", BootstrapExpect: 2},
+		logger:    log.New(ioutil.Discard, "", 0),
+		raftPeers: store,
+	}
+
+	if !s.bootstrapped(servers) {
+		t.Fatalf("expected lowest-address server to bootstrap")
+	}
+	if !store.setCalled {
+		t.Fatalf("expected SetPeers to be called by the initiator")
+	}
+	if len(store.peers) != 2 {
+		t.Fatalf("bad: %#v", store.peers)
+	}
+}
+
+func TestBootstrapped_NonInitiatorWaits(t *testing.T) {
+	servers := []*ServerParts{
+		newTestServerParts("b", "127.0.0.2"),
+		newTestServerParts("a", "127.0.0.1"),
+	}
+
+	store := &fakePeerStore{}
+	s := &Server{
+		config:    &Config{NodeName: "b", BootstrapExpect: 2},
+		logger:    log.New(ioutil.Discard, "", 0),
+		raftPeers: store,
+	}
+
+	if s.bootstrapped(servers) {
+		t.Fatalf("expected non-initiator server not to bootstrap")
+	}
+	if store.setCalled {
+		t.Fatalf("non-initiator must not call SetPeers")
+	}
+}
+
+func TestBootstrapped_BelowExpectCount(t *testing.T) {
+	servers := []*ServerParts{newTestServerParts("a", "127.0.0.1")}
+
+	store := &fakePeerStore{}
+	s := &Server{
+		config:    &Config{NodeName: "a", BootstrapExpect: 3},
+		logger:    log.New(ioutil.Discard, "", 0),
+		raftPeers: store,
+	}
+
+	if s.bootstrapped(servers) {
+		t.Fatalf("expected bootstrap to wait for BootstrapExpect servers")
+	}
+	if store.setCalled {
+		t.Fatalf("must not call SetPeers before BootstrapExpect is satisfied")
+	}
+}
+
+func TestBootstrapped_AlreadySeeded(t *testing.T) {
+	servers := []*ServerParts{newTestServerParts("a", "127.0.0.1")}
+
+	store := &fakePeerStore{peers: []net.Addr{&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8300}}}
+	s := &Server{
+		config:    &Config{NodeName: "a", BootstrapExpect: 3},
+		logger:    log.New(ioutil.Discard, "", 0),
+		raftPeers: store,
+	}
+
+	if !s.bootstrapped(servers) {
+		t.Fatalf("expected an already-seeded peer store to short-circuit bootstrap")
+	}
+}
+
+func TestAutopilotMarkMissing_ReapGate(t *testing.T) {
+	s := &Server{}
+
+	missingFor := s.autopilotMarkMissing("127.0.0.1:8300")
+	if missingFor >= defaultLastContactThreshold {
+		t.Fatalf("a freshly-missing peer shouldn't already be past the threshold")
+	}
+
+	// A peer seen again before the threshold elapses must reset its timer.
+	s.autopilotClearMissing("127.0.0.1:8300")
+	if _, ok := s.autopilotMissing["127.0.0.1:8300"]; ok {
+		t.Fatalf("expected missing bookkeeping to be cleared")
+	}
+}
+
+func TestAutopilotMarkStable_PromotionGate(t *testing.T) {
+	s := &Server{}
+
+	key := "127.0.0.1:8300"
+	s.autopilotMissing = nil
+	s.autopilotStableSince = map[string]time.Time{key: time.Now().Add(-2 * defaultServerStabilizationTime)}
+
+	stableFor := s.autopilotMarkStable(key)
+	if stableFor < defaultServerStabilizationTime {
+		t.Fatalf("expected a long-stable candidate to already clear the stabilization gate")
+	}
+
+	s.autopilotClearStable(key)
+	if _, ok := s.autopilotStableSince[key]; ok {
+		t.Fatalf("expected stable bookkeeping to be cleared")
+	}
+}
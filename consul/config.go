@@ -0,0 +1,47 @@
+package consul
+
+import "time"
+
+// Config is used to configure the Consul server.
+type Config struct {
+	// Datacenter is the datacenter this server is a member of.
+	Datacenter string
+
+	// NodeName is this server's Serf/Raft identity, used to pick itself
+	// out of a list of its own LAN peers (e.g. in bootstrapped).
+	NodeName string
+
+	// BootstrapExpect, if non-zero, enables bootstrap-expect mode: the
+	// Raft peer set is only seeded once this many consul-tagged servers
+	// are visible and alive in the LAN pool. See bootstrapped.
+	BootstrapExpect int
+
+	// ReapTimeout is how long a failed server is given to return to the
+	// LAN pool before its Raft peer entry is removed. Defaults to
+	// defaultReapTimeout if zero.
+	ReapTimeout time.Duration
+
+	// LastContactThreshold is how long a known Raft peer can be absent
+	// from the LAN pool before autopilot reaps it. Defaults to
+	// defaultLastContactThreshold if zero.
+	LastContactThreshold time.Duration
+
+	// ServerStabilizationTime is how long a candidate server must be
+	// continuously alive in the LAN pool before autopilot promotes it to
+	// a Raft peer. Defaults to defaultServerStabilizationTime if zero.
+	ServerStabilizationTime time.Duration
+
+	// ACLEnabled turns on ACL token resolution for RPC endpoints that
+	// gate on it. With it off, resolveToken always allows the request.
+	ACLEnabled bool
+}
+
+// DefaultConfig returns a Config with the recommended defaults for every
+// field autopilot and the reap path fall back to when left unset.
+func DefaultConfig() *Config {
+	return &Config{
+		ReapTimeout:             defaultReapTimeout,
+		LastContactThreshold:    defaultLastContactThreshold,
+		ServerStabilizationTime: defaultServerStabilizationTime,
+	}
+}
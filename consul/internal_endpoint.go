@@ -0,0 +1,134 @@
+package consul
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-uuid"
+)
+
+// Internal endpoint is used to query miscellaneous internal APIs that don't
+// warrant an endpoint of their own.
+type Internal struct {
+	srv *Server
+}
+
+// EventFireRequest is used to fire a new user event, or, when ID is already
+// set, to re-fire an event relayed in from another datacenter.
+type EventFireRequest struct {
+	Datacenter string
+	Token      string
+
+	// ID is left blank on the original fire and assigned here; a relay
+	// call from another datacenter's leader sets it so the re-fired
+	// event keeps the same dedup identity everywhere it's seen.
+	ID string
+
+	Name    string
+	Payload []byte
+
+	NodeFilter    string
+	ServiceFilter string
+	TagFilter     string
+
+	// RelayDatacenters lists other datacenters this event should also be
+	// delivered to. Left unset on a relay call, since relays are single
+	// hop only.
+	RelayDatacenters []string
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *EventFireRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// EventFireResponse is returned in response to a user event fire.
+type EventFireResponse struct{}
+
+// EventFire is used to fire a new user event on the LAN Serf pool of the
+// request's datacenter.
+func (m *Internal) EventFire(args *EventFireRequest, reply *EventFireResponse) error {
+	if done, err := m.srv.forward("Internal.EventFire", args, args, reply); done {
+		return err
+	}
+
+	// Fire events requires write access to the given event name.
+	acl, err := m.srv.resolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if acl != nil && !acl.EventWrite(args.Name) {
+		return permissionDeniedErr
+	}
+
+	id := args.ID
+	if id == "" {
+		id, err = uuid.GenerateUUID()
+		if err != nil {
+			return fmt.Errorf("failed to generate event ID: %v", err)
+		}
+	}
+
+	params := &UserEventParam{
+		Version:          userEventMaxVersion,
+		ID:               id,
+		Name:             args.Name,
+		Payload:          args.Payload,
+		NodeFilter:       args.NodeFilter,
+		ServiceFilter:    args.ServiceFilter,
+		TagFilter:        args.TagFilter,
+		RelayDatacenters: args.RelayDatacenters,
+	}
+	payload, err := encodeUserEvent(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	// Coalesce on the Serf side so a flood of fires for the same event
+	// name doesn't queue up redundant gossip broadcasts.
+	if err := m.srv.serfLAN.UserEvent(args.Name, payload, true); err != nil {
+		return fmt.Errorf("failed to fire event: %v", err)
+	}
+	return nil
+}
+
+// EventListRequest is used to query the recent events known to this server.
+type EventListRequest struct {
+	Datacenter string
+	Token      string
+	Name       string
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *EventListRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// EventListResponse returns the events known to the queried server.
+type EventListResponse struct {
+	Events []*UserEvent
+}
+
+// EventList returns the contents of the bounded event buffer of the
+// request datacenter's leader (forward hands the request to it when this
+// server isn't the leader), optionally filtered by event name.
+func (m *Internal) EventList(args *EventListRequest, reply *EventListResponse) error {
+	if done, err := m.srv.forward("Internal.EventList", args, args, reply); done {
+		return err
+	}
+
+	acl, err := m.srv.resolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range m.srv.recentUserEvents() {
+		if args.Name != "" && event.Name != args.Name {
+			continue
+		}
+		if acl != nil && !acl.EventRead(event.Name) {
+			continue
+		}
+		reply.Events = append(reply.Events, event)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package consul
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/consul/consul/structs"
+)
+
+// Operator endpoint is used to perform low-level operator tasks for Consul.
+type Operator struct {
+	srv *Server
+}
+
+// RaftGetConfiguration is used to dump the current Raft peer set in a
+// re-published, easily consumed format.
+func (op *Operator) RaftGetConfiguration(args *structs.RaftConfigurationRequest, reply *structs.RaftConfigurationResponse) error {
+	if done, err := op.srv.forward("Operator.RaftGetConfiguration", args, args, reply); done {
+		return err
+	}
+
+	// This action requires operator read access.
+	acl, err := op.srv.resolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if acl != nil && !acl.OperatorRead() {
+		return permissionDeniedErr
+	}
+
+	peers, err := op.srv.raftPeers.Peers()
+	if err != nil {
+		return fmt.Errorf("failed to get raft peers: %v", err)
+	}
+	leader := op.srv.raft.Leader()
+	reply.Index = op.srv.raft.AppliedIndex()
+
+	for _, peer := range peers {
+		entry := structs.RaftServer{
+			ID:      peer.String(),
+			Node:    "(unknown)",
+			Address: peer.String(),
+			Leader:  leader != nil && peer.String() == leader.String(),
+			Voter:   true,
+		}
+
+		// Map the Raft peer address back to its Serf metadata, using the
+		// LAN members as the source of truth.
+		for _, m := range op.srv.serfLAN.Members() {
+			ok, parts := op.srv.isConsulServer(m)
+			if !ok {
+				continue
+			}
+			if peer.String() != parts.Addr.String() {
+				continue
+			}
+			entry.Node = m.Name
+			entry.Build = parts.Build
+			entry.Voter = !parts.NonVoter
+			if parts.ID != "" {
+				entry.ID = parts.ID
+			}
+			break
+		}
+
+		reply.Servers = append(reply.Servers, entry)
+	}
+	return nil
+}
+
+// RaftRemovePeerByAddress is used to kick a stale peer (one that is in the
+// Raft configuration but no longer known to Serf or the catalog) out of the
+// cluster by address. This is the recovery path for when the automatic
+// leave/fail peer reaping can't reach the peer in question (split brain,
+// corrupted node, etc).
+func (op *Operator) RaftRemovePeerByAddress(args *structs.RaftRemovePeerRequest, reply *struct{}) error {
+	if done, err := op.srv.forward("Operator.RaftRemovePeerByAddress", args, args, reply); done {
+		return err
+	}
+
+	// This is a destructive operation and requires operator write access.
+	acl, err := op.srv.resolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if acl != nil && !acl.OperatorWrite() {
+		return permissionDeniedErr
+	}
+
+	// Only the leader can make changes to the Raft configuration.
+	if !op.srv.IsLeader() {
+		return fmt.Errorf("node is not the leader")
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", args.Address)
+	if err != nil {
+		return fmt.Errorf("failed to resolve address %q: %v", args.Address, err)
+	}
+
+	future := op.srv.raft.RemovePeer(addr)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("failed to remove raft peer %q: %v", args.Address, err)
+	}
+
+	op.srv.logger.Printf("[WARN] consul.operator: Removed Raft peer %s via operator API", args.Address)
+	return nil
+}
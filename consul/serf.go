@@ -1,6 +1,7 @@
 package consul
 
 import (
+	"fmt"
 	"github.com/hashicorp/raft"
 	"github.com/hashicorp/serf/serf"
 	"net"
@@ -46,6 +47,11 @@ func (s *Server) wanEventHandler() {
 			case serf.EventMemberFailed:
 				s.remoteFailed(e.(serf.MemberEvent))
 			case serf.EventUser:
+				// User events only propagate within a datacenter over the
+				// LAN pool. The WAN pool carries topology information only,
+				// so cross-DC delivery goes through an explicit
+				// Internal.EventFire RPC to the target leader instead of
+				// riding the WAN gossip itself (see localEvent).
 			default:
 				s.logger.Printf("[WARN] Unhandled LAN Serf Event: %#v", e)
 			}
@@ -60,46 +66,71 @@ func (s *Server) wanEventHandler() {
 func (s *Server) localJoin(me serf.MemberEvent) {
 	// Check for consul members
 	for _, m := range me.Members {
-		ok, dc, port := s.isConsulServer(m)
+		ok, parts := s.isConsulServer(m)
 		if !ok {
 			continue
 		}
-		if dc != s.config.Datacenter {
+		if parts.Datacenter != s.config.Datacenter {
 			s.logger.Printf("[WARN] Consul server %s for datacenter %s has joined wrong cluster",
-				m.Name, dc)
+				m.Name, parts.Datacenter)
 			continue
 		}
-		go s.joinConsulServer(m, port)
+		go s.joinConsulServer(m, parts)
 	}
 }
 
 // localLeave is used to handle leave events on the lan serf cluster
 func (s *Server) localLeave(me serf.MemberEvent) {
+	for _, m := range me.Members {
+		ok, parts := s.isConsulServer(m)
+		if !ok {
+			continue
+		}
+		go s.removeConsulServer(m, parts)
+	}
 }
 
 // localFailed is used to handle fail events on the lan serf cluster
 func (s *Server) localFailed(me serf.MemberEvent) {
-}
+	for _, m := range me.Members {
+		ok, parts := s.isConsulServer(m)
+		if !ok {
+			continue
+		}
+
+		// Give the node a chance to come back before we reap its Raft
+		// peer entry, so a brief flap doesn't eject a healthy voter.
+		reapTimeout := s.config.ReapTimeout
+		if reapTimeout == 0 {
+			reapTimeout = defaultReapTimeout
+		}
 
-// localEvent is used to handle events on the lan serf cluster
-func (s *Server) localEvent(ue serf.UserEvent) {
+		member := m
+		serverParts := parts
+		time.AfterFunc(reapTimeout, func() {
+			if memberStatus(s.serfLAN.Members(), member.Name) == serf.StatusAlive {
+				return
+			}
+			s.removeConsulServer(member, serverParts)
+		})
+	}
 }
 
 // remoteJoin is used to handle join events on the wan serf cluster
 func (s *Server) remoteJoin(me serf.MemberEvent) {
 	for _, m := range me.Members {
-		ok, dc, port := s.isConsulServer(m)
+		ok, parts := s.isConsulServer(m)
 		if !ok {
-			s.logger.Printf("[WARN] Non-Consul server in WAN pool: %s %s", m.Name)
+			s.logger.Printf("[WARN] Non-Consul server in WAN pool: %s", m.Name)
 			continue
 		}
-		var addr net.Addr = &net.TCPAddr{IP: m.Addr, Port: port}
-		s.logger.Printf("[INFO] Adding Consul server (Datacenter: %s) (Addr: %s)", dc, addr)
+		addr := parts.Addr
+		s.logger.Printf("[INFO] Adding Consul server (Datacenter: %s) (Addr: %s)", parts.Datacenter, addr)
 
 		// Check if this server is known
 		found := false
 		s.remoteLock.Lock()
-		existing := s.remoteConsuls[dc]
+		existing := s.remoteConsuls[parts.Datacenter]
 		for _, e := range existing {
 			if e.String() == addr.String() {
 				found = true
@@ -109,7 +140,7 @@ func (s *Server) remoteJoin(me serf.MemberEvent) {
 
 		// Add ot the list if not known
 		if !found {
-			s.remoteConsuls[dc] = append(existing, addr)
+			s.remoteConsuls[parts.Datacenter] = append(existing, addr)
 		}
 		s.remoteLock.Unlock()
 	}
@@ -118,16 +149,16 @@ func (s *Server) remoteJoin(me serf.MemberEvent) {
 // remoteFailed is used to handle fail events on the wan serf cluster
 func (s *Server) remoteFailed(me serf.MemberEvent) {
 	for _, m := range me.Members {
-		ok, dc, port := s.isConsulServer(m)
+		ok, parts := s.isConsulServer(m)
 		if !ok {
 			continue
 		}
-		var addr net.Addr = &net.TCPAddr{IP: m.Addr, Port: port}
-		s.logger.Printf("[INFO] Removing Consul server (Datacenter: %s) (Addr: %s)", dc, addr)
+		addr := parts.Addr
+		s.logger.Printf("[INFO] Removing Consul server (Datacenter: %s) (Addr: %s)", parts.Datacenter, addr)
 
 		// Remove the server if known
 		s.remoteLock.Lock()
-		existing := s.remoteConsuls[dc]
+		existing := s.remoteConsuls[parts.Datacenter]
 		n := len(existing)
 		for i := 0; i < n; i++ {
 			if existing[i].String() == addr.String() {
@@ -140,48 +171,127 @@ func (s *Server) remoteFailed(me serf.MemberEvent) {
 
 		// Trim the list if all known consuls are dead
 		if n == 0 {
-			delete(s.remoteConsuls, dc)
+			delete(s.remoteConsuls, parts.Datacenter)
 		} else {
-			s.remoteConsuls[dc] = existing
+			s.remoteConsuls[parts.Datacenter] = existing
 		}
 		s.remoteLock.Unlock()
 	}
 }
 
-// Returns if a member is a consul server. Returns a bool,
-// the data center, and the rpc port
-func (s *Server) isConsulServer(m serf.Member) (bool, string, int) {
+// ServerParts holds the parsed metadata for a Consul server, as recovered
+// from a Serf member's tags (or, for older peers during a rolling upgrade,
+// from the legacy Role string).
+type ServerParts struct {
+	Name        string
+	ID          string
+	Datacenter  string
+	Port        int
+	RaftVersion int
+	Build       string
+	Expect      int
+	NonVoter    bool
+	Addr        net.Addr
+	Status      serf.MemberStatus
+}
+
+func (s *ServerParts) String() string {
+	return fmt.Sprintf("%s (Addr: %s) (DC: %s)", s.Name, s.Addr, s.Datacenter)
+}
+
+// isConsulServer returns true plus the parsed server metadata if a given
+// Serf member is a Consul server. Newer peers advertise their metadata as
+// Serf tags (role, dc, port, id, raft_vsn, build, expect, non_voter), which
+// can carry fields the old "consul:<dc>:<port>" Role string never could.
+// Older peers still encountered during a rolling upgrade are handled by
+// falling back to that legacy Role string.
+func (s *Server) isConsulServer(m serf.Member) (bool, *ServerParts) {
+	if m.Tags["role"] == "consul" {
+		datacenter := m.Tags["dc"]
+		port, err := strconv.Atoi(m.Tags["port"])
+		if err != nil {
+			s.logger.Printf("[ERR] Failed to parse port tag for %q: %v", m.Name, err)
+			return false, nil
+		}
+
+		var raftVsn int
+		if raftVsnStr, ok := m.Tags["raft_vsn"]; ok {
+			raftVsn, err = strconv.Atoi(raftVsnStr)
+			if err != nil {
+				s.logger.Printf("[ERR] Failed to parse raft_vsn tag for %q: %v", m.Name, err)
+			}
+		}
+
+		var expect int
+		if expectStr, ok := m.Tags["expect"]; ok {
+			expect, err = strconv.Atoi(expectStr)
+			if err != nil {
+				s.logger.Printf("[ERR] Failed to parse expect tag for %q: %v", m.Name, err)
+			}
+		}
+
+		return true, &ServerParts{
+			Name:        m.Name,
+			ID:          m.Tags["id"],
+			Datacenter:  datacenter,
+			Port:        port,
+			RaftVersion: raftVsn,
+			Build:       m.Tags["build"],
+			Expect:      expect,
+			NonVoter:    m.Tags["non_voter"] == "1",
+			Addr:        &net.TCPAddr{IP: m.Addr, Port: port},
+			Status:      m.Status,
+		}
+	}
+
+	// Legacy fallback: a peer running a version before tagged metadata
+	// existed only ever advertised "consul:<dc>:<port>" as its Role.
 	role := m.Role
 	if !strings.HasPrefix(role, "consul:") {
-		return false, "", 0
+		return false, nil
 	}
-
-	parts := strings.SplitN(role, ":", 3)
-	datacenter := parts[1]
-	port_str := parts[2]
-	port, err := strconv.Atoi(port_str)
+	fields := strings.SplitN(role, ":", 3)
+	if len(fields) != 3 {
+		s.logger.Printf("[ERR] Failed to parse role: %s", role)
+		return false, nil
+	}
+	datacenter := fields[1]
+	port, err := strconv.Atoi(fields[2])
 	if err != nil {
 		s.logger.Printf("[ERR] Failed to parse role: %s", role)
-		return false, "", 0
+		return false, nil
+	}
+	return true, &ServerParts{
+		Name:       m.Name,
+		Datacenter: datacenter,
+		Port:       port,
+		Addr:       &net.TCPAddr{IP: m.Addr, Port: port},
+		Status:     m.Status,
 	}
-
-	return true, datacenter, port
 }
 
-// joinConsulServer is used to try to join another consul server
-func (s *Server) joinConsulServer(m serf.Member, port int) {
+// joinConsulServer is used to try to add another consul server as a Raft
+// peer. This is a single best-effort attempt rather than a retry loop: if
+// BootstrapExpect hasn't been satisfied yet, or the attempt fails, the
+// periodic autopilot goroutine sweeps the whole cluster on an interval and
+// will pick up anything missed here.
+func (s *Server) joinConsulServer(m serf.Member, parts *ServerParts) {
 	if m.Name == s.config.NodeName {
 		return
 	}
-	var addr net.Addr = &net.TCPAddr{IP: m.Addr, Port: port}
-	var future raft.Future
 
-CHECK:
-	// Get the Raft peers
+	// Until BootstrapExpect is satisfied, leave new peers for autopilot
+	// to add once the initial bootstrap has happened, so a fresh cluster
+	// doesn't elect on a single node and then reshape as peers trickle in.
+	if s.config.BootstrapExpect > 0 && !s.bootstrapped(s.lanConsulServers()) {
+		return
+	}
+
+	addr := parts.Addr
 	peers, err := s.raftPeers.Peers()
 	if err != nil {
 		s.logger.Printf("[ERR] Failed to get raft peers: %v", err)
-		goto WAIT
+		return
 	}
 
 	// Bail if this node is already a peer
@@ -196,11 +306,65 @@ CHECK:
 		return
 	}
 
-	// Attempt to add as a peer
-	future = s.raft.AddPeer(addr)
+	future := s.raft.AddPeer(addr)
 	if err := future.Error(); err != nil {
 		s.logger.Printf("[ERR] Failed to add raft peer: %v", err)
+	}
+}
+
+// defaultReapTimeout is how long we wait for a failed server to return to
+// the LAN pool before we give up and remove its Raft peer entry, if
+// Config.ReapTimeout isn't set.
+const defaultReapTimeout = 3 * time.Minute
+
+// removeConsulServer is used to try to remove a consul server that has
+// left or failed from the Raft configuration
+func (s *Server) removeConsulServer(m serf.Member, parts *ServerParts) {
+	if parts.Datacenter != s.config.Datacenter {
+		return
+	}
+
+	addr := parts.Addr
+	var future raft.Future
+
+CHECK:
+	// Only the leader should attempt removal, otherwise every server
+	// that observes the event would race to issue the same RemovePeer
+	// and flood the FSM with duplicate log entries. This is re-checked
+	// on every retry pass rather than once on entry, so a node that
+	// loses leadership mid-retry (a normal event right after a peer
+	// failure, which is exactly when elections are likely) bails out
+	// instead of spinning forever logging the same failed RemovePeer.
+	if !s.IsLeader() {
+		return
+	}
+
+	// Get the Raft peers
+	peers, err := s.raftPeers.Peers()
+	if err != nil {
+		s.logger.Printf("[ERR] Failed to get raft peers: %v", err)
+		return
+	}
+
+	// Bail if this node is not actually a peer, avoids a needless log entry
+	found := false
+	for _, p := range peers {
+		if p.String() == addr.String() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	// Attempt to remove as a peer
+	future = s.raft.RemovePeer(addr)
+	if err := future.Error(); err != nil && err != raft.ErrUnknownPeer {
+		s.logger.Printf("[ERR] Failed to remove raft peer %v: %v", addr, err)
+		goto WAIT
 	} else {
+		s.logger.Printf("[INFO] Removed Consul server %s", parts)
 		return
 	}
 
@@ -0,0 +1,96 @@
+package consul
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/serf/serf"
+)
+
+// testServer returns a scratch directory and a Server with just enough
+// state for the unit tests in this package (no Serf pools, no Raft).
+func testServer(t *testing.T) (string, *Server) {
+	dir, err := ioutil.TempDir("", "consul")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return dir, &Server{
+		config:     &Config{Datacenter: "dc1", NodeName: "node1"},
+		logger:     log.New(ioutil.Discard, "", 0),
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+func TestIsConsulServer_Tags(t *testing.T) {
+	dir, s := testServer(t)
+	defer os.RemoveAll(dir)
+	defer s.Shutdown()
+
+	m := serf.Member{
+		Name: "foo",
+		Addr: net.ParseIP("127.0.0.1"),
+		Tags: map[string]string{
+			"role":      "consul",
+			"dc":        "dc1",
+			"port":      "8300",
+			"id":        "abc-123",
+			"raft_vsn":  "2",
+			"build":     "0.8.0",
+			"expect":    "3",
+			"non_voter": "1",
+		},
+	}
+
+	ok, parts := s.isConsulServer(m)
+	if !ok {
+		t.Fatalf("expected %#v to be a consul server", m)
+	}
+	if parts.Datacenter != "dc1" || parts.Port != 8300 {
+		t.Fatalf("bad: %#v", parts)
+	}
+	if parts.ID != "abc-123" || parts.RaftVersion != 2 || parts.Build != "0.8.0" {
+		t.Fatalf("bad: %#v", parts)
+	}
+	if parts.Expect != 3 || !parts.NonVoter {
+		t.Fatalf("bad: %#v", parts)
+	}
+}
+
+func TestIsConsulServer_LegacyRole(t *testing.T) {
+	dir, s := testServer(t)
+	defer os.RemoveAll(dir)
+	defer s.Shutdown()
+
+	m := serf.Member{
+		Name: "foo",
+		Addr: net.ParseIP("127.0.0.1"),
+		Role: "consul:dc1:8300",
+	}
+
+	ok, parts := s.isConsulServer(m)
+	if !ok {
+		t.Fatalf("expected %#v to be a consul server", m)
+	}
+	if parts.Datacenter != "dc1" || parts.Port != 8300 {
+		t.Fatalf("bad: %#v", parts)
+	}
+
+	// Legacy members don't carry any of the newer tag-derived fields.
+	if parts.ID != "" || parts.RaftVersion != 0 || parts.NonVoter {
+		t.Fatalf("bad: %#v", parts)
+	}
+}
+
+func TestIsConsulServer_NotAServer(t *testing.T) {
+	dir, s := testServer(t)
+	defer os.RemoveAll(dir)
+	defer s.Shutdown()
+
+	m := serf.Member{Name: "foo", Tags: map[string]string{"role": "node"}}
+	if ok, _ := s.isConsulServer(m); ok {
+		t.Fatalf("expected %#v to not be a consul server", m)
+	}
+}
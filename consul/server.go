@@ -0,0 +1,188 @@
+package consul
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/hashicorp/serf/serf"
+)
+
+// Server is a Consul server: it runs the LAN/WAN Serf pools, participates
+// in the Raft consensus group for its datacenter, and exposes the RPC
+// endpoints agents and other servers talk to.
+type Server struct {
+	config *Config
+	logger *log.Logger
+
+	serfLAN    *serf.Serf
+	serfWAN    *serf.Serf
+	eventChLAN chan serf.Event
+	eventChWAN chan serf.Event
+
+	raft      *raft.Raft
+	raftPeers raft.PeerStore
+
+	rpcServer *rpc.Server
+
+	remoteLock    sync.Mutex
+	remoteConsuls map[string][]net.Addr
+
+	eventLock    sync.Mutex
+	recentEvents map[string]time.Time
+	eventBuf     []*UserEvent
+	eventIndex   int
+
+	autopilotLock        sync.Mutex
+	autopilotMissing     map[string]time.Time
+	autopilotStableSince map[string]time.Time
+
+	shutdownCh   chan struct{}
+	shutdownLock sync.Mutex
+	shutdown     bool
+}
+
+// NewServer wires up a Server around an already-established LAN/WAN Serf
+// pool and Raft instance (their transports, snapshot stores, and log
+// stores are all deployment-specific and set up by the caller) and
+// registers this package's RPC endpoints and background loops. It does
+// not start the Serf pools themselves; the caller is expected to have
+// already joined them before or after calling NewServer.
+func NewServer(config *Config, logger *log.Logger, serfLAN, serfWAN *serf.Serf, raftNode *raft.Raft, raftPeers raft.PeerStore, rpcServer *rpc.Server) (*Server, error) {
+	if config.Datacenter == "" {
+		return nil, fmt.Errorf("consul: Datacenter must be set")
+	}
+	if config.NodeName == "" {
+		return nil, fmt.Errorf("consul: NodeName must be set")
+	}
+
+	s := &Server{
+		config:        config,
+		logger:        logger,
+		serfLAN:       serfLAN,
+		serfWAN:       serfWAN,
+		eventChLAN:    make(chan serf.Event, 256),
+		eventChWAN:    make(chan serf.Event, 256),
+		raft:          raftNode,
+		raftPeers:     raftPeers,
+		rpcServer:     rpcServer,
+		remoteConsuls: make(map[string][]net.Addr),
+		shutdownCh:    make(chan struct{}),
+	}
+
+	if err := s.setupRPC(); err != nil {
+		return nil, fmt.Errorf("consul: failed to register RPC endpoints: %v", err)
+	}
+
+	go s.lanEventHandler()
+	go s.wanEventHandler()
+	go s.autopilot()
+
+	return s, nil
+}
+
+// setupRPC registers this package's RPC endpoints against the server's
+// rpc.Server so they're reachable once the caller starts accepting
+// connections on it.
+func (s *Server) setupRPC() error {
+	if err := s.rpcServer.Register(&Operator{srv: s}); err != nil {
+		return err
+	}
+	if err := s.rpcServer.Register(&Internal{srv: s}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsLeader returns true if this server is the current Raft leader for its
+// datacenter.
+func (s *Server) IsLeader() bool {
+	return s.raft.Leader() != nil && s.raft.State() == raft.Leader
+}
+
+// Shutdown stops this server's background goroutines. It's safe to call
+// more than once.
+func (s *Server) Shutdown() error {
+	s.shutdownLock.Lock()
+	defer s.shutdownLock.Unlock()
+	if s.shutdown {
+		return nil
+	}
+	s.shutdown = true
+	close(s.shutdownCh)
+	return nil
+}
+
+// resolveToken resolves an ACL token to the policy it grants. A nil ACL
+// with a nil error means the request should be allowed unconditionally,
+// which is always the case while ACLs aren't enabled for this datacenter.
+func (s *Server) resolveToken(token string) (*ACL, error) {
+	if !s.config.ACLEnabled {
+		return nil, nil
+	}
+	return resolveACLToken(token)
+}
+
+// forward is used by an RPC endpoint to either redirect a cross-DC request
+// to the target datacenter's leader, or, for a request scoped to this
+// datacenter, hand it off to the Raft leader if this server isn't it.
+// done is true if the caller has nothing further to do, whether because
+// the request was forwarded or because forwarding failed; in either case
+// the returned error is the one the caller should return.
+func (s *Server) forward(method string, info interface {
+	RequestDatacenter() string
+}, args interface{}, reply interface{}) (bool, error) {
+	dc := info.RequestDatacenter()
+	if dc != "" && dc != s.config.Datacenter {
+		return true, s.forwardDC(method, dc, args, reply)
+	}
+
+	if s.IsLeader() {
+		return false, nil
+	}
+
+	leader := s.raft.Leader()
+	if leader == nil {
+		return true, fmt.Errorf("consul: no leader")
+	}
+	return true, s.rpcCall(leader.String(), method, args, reply)
+}
+
+// forwardDC forwards an RPC to a known server in the given remote
+// datacenter.
+func (s *Server) forwardDC(method, dc string, args interface{}, reply interface{}) error {
+	s.remoteLock.Lock()
+	servers := s.remoteConsuls[dc]
+	s.remoteLock.Unlock()
+
+	if len(servers) == 0 {
+		return fmt.Errorf("consul: no servers known for datacenter %q", dc)
+	}
+	return s.rpcCall(servers[0].String(), method, args, reply)
+}
+
+// rpcCall dials addr and makes a single blocking RPC against it.
+func (s *Server) rpcCall(addr, method string, args, reply interface{}) error {
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("consul: failed to dial %s: %v", addr, err)
+	}
+	defer client.Close()
+	return client.Call(method, args, reply)
+}
+
+// LocalRPC dispatches method against this server's own registered RPC
+// endpoints without going out over the network, for an agent running
+// in the same process as its server.
+func (s *Server) LocalRPC(method string, args interface{}, reply interface{}) error {
+	clientConn, serverConn := net.Pipe()
+	go s.rpcServer.ServeConn(serverConn)
+
+	client := rpc.NewClient(clientConn)
+	defer client.Close()
+	return client.Call(method, args, reply)
+}
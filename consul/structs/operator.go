@@ -0,0 +1,70 @@
+package structs
+
+// RaftServer has information about a server in the Raft configuration
+type RaftServer struct {
+	// ID is the unique ID for the server: the value of its id Serf tag,
+	// or its address if it advertises no id.
+	ID string
+
+	// Node is the node name of the server, as known by Consul, or this
+	// will be set to "(unknown)" otherwise.
+	Node string
+
+	// Address is the IP:port of the server, used for Raft communications.
+	Address string
+
+	// Leader is true if this server is the current cluster leader.
+	Leader bool
+
+	// Voter is false if the server advertises the non_voter Serf tag,
+	// and true otherwise (including when its Serf member isn't found).
+	Voter bool
+
+	// Build is the Consul version of the server, if known.
+	Build string
+}
+
+// RaftConfigurationResponse is returned when querying for the current
+// Raft configuration.
+type RaftConfigurationResponse struct {
+	// Servers has the list of servers in the Raft configuration.
+	Servers []RaftServer
+
+	// Index is the server's applied Raft index when the configuration
+	// was read.
+	Index uint64
+}
+
+// RaftConfigurationRequest is used by the Operator endpoint to query the
+// current Raft peer configuration for a datacenter.
+type RaftConfigurationRequest struct {
+	// Datacenter is the target this request is intended for.
+	Datacenter string
+
+	// Token is the ACL token to use for this request, if any.
+	Token string
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (op *RaftConfigurationRequest) RequestDatacenter() string {
+	return op.Datacenter
+}
+
+// RaftRemovePeerRequest is used by the Operator endpoint to remove a peer
+// from the Raft configuration, by address, in the case of a stale or
+// unrecoverable peer that must be removed by hand.
+type RaftRemovePeerRequest struct {
+	// Datacenter is the target this request is intended for.
+	Datacenter string
+
+	// Address is the peer to remove, in its IP:port Raft form.
+	Address string
+
+	// Token is the ACL token to use for this request, if any.
+	Token string
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (op *RaftRemovePeerRequest) RequestDatacenter() string {
+	return op.Datacenter
+}
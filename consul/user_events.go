@@ -0,0 +1,196 @@
+package consul
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/serf/serf"
+)
+
+// userEventMaxVersion is the current version of the user event payload.
+const userEventMaxVersion = 1
+
+// maxEventBuffer is how many recent events each server keeps around to
+// back the Internal.EventList API.
+const maxEventBuffer = 256
+
+// userEventTTL governs how long we remember an event ID to reject replays
+// that loop back around through WAN<->LAN gossip.
+const userEventTTL = 5 * time.Minute
+
+// UserEventParam is the payload we paint onto the Serf user event, so that
+// it survives a LAN gossip round and carries enough metadata to dedup,
+// record, and optionally relay the event cross-DC.
+type UserEventParam struct {
+	// Version of the event payload format.
+	Version int
+
+	// ID uniquely identifies this event, and is used to dedup re-entry
+	// through WAN<->LAN gossip loops; it is also kept on the recorded
+	// event returned by Internal.EventList.
+	ID string
+
+	// Name of the event.
+	Name string
+
+	// Payload is the opaque user-supplied event payload.
+	Payload []byte
+
+	// NodeFilter is a regular expression used to filter which nodes
+	// should act on this event.
+	NodeFilter string
+
+	// ServiceFilter is a regular expression used to filter on nodes
+	// providing a given service.
+	ServiceFilter string
+
+	// TagFilter further filters ServiceFilter by tag, and is only used
+	// in conjunction with it.
+	TagFilter string
+
+	// RelayDatacenters lists other datacenters this event should be
+	// forwarded to, for local re-broadcast there. Only consulted by the
+	// leader of the datacenter that receives the original fire.
+	RelayDatacenters []string
+}
+
+// decodeUserEvent decodes a UserEventParam from a Serf user event payload.
+func decodeUserEvent(payload []byte) (*UserEventParam, error) {
+	var params UserEventParam
+	if err := json.Unmarshal(payload, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode user event: %v", err)
+	}
+	if params.Version > userEventMaxVersion {
+		return nil, fmt.Errorf("unsupported user event version: %d", params.Version)
+	}
+	return &params, nil
+}
+
+// encodeUserEvent encodes a UserEventParam for use as a Serf user event
+// payload.
+func encodeUserEvent(params *UserEventParam) ([]byte, error) {
+	return json.Marshal(params)
+}
+
+// UserEvent is a record of a fired event, kept around in a bounded ring
+// buffer so recent activity can be inspected via Internal.EventList.
+type UserEvent struct {
+	ID            string
+	Name          string
+	Payload       []byte
+	NodeFilter    string
+	ServiceFilter string
+	TagFilter     string
+	LTime         uint64
+}
+
+// localEvent is used to handle incoming user events on the LAN Serf
+// cluster. It dedups against recently seen event IDs (so a gossip loop
+// between the WAN and LAN pools can't amplify the same fire indefinitely),
+// records the event for Internal.EventList, and, if this node is the leader,
+// relays it to any other datacenters the firer tagged.
+func (s *Server) localEvent(ue serf.UserEvent) {
+	params, err := decodeUserEvent(ue.Payload)
+	if err != nil {
+		s.logger.Printf("[ERR] consul: Failed to decode user event %q: %v", ue.Name, err)
+		return
+	}
+
+	if s.dedupUserEvent(params.ID) {
+		return
+	}
+	s.recordUserEvent(params)
+
+	if len(params.RelayDatacenters) > 0 && s.IsLeader() {
+		s.relayUserEvent(params)
+	}
+}
+
+// dedupUserEvent reports whether the given event ID has already been
+// handled within userEventTTL, and marks it seen if not.
+func (s *Server) dedupUserEvent(id string) bool {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+
+	now := time.Now()
+	if seen, ok := s.recentEvents[id]; ok && now.Sub(seen) < userEventTTL {
+		return true
+	}
+
+	if s.recentEvents == nil {
+		s.recentEvents = make(map[string]time.Time)
+	}
+	s.recentEvents[id] = now
+
+	// Opportunistically reap stale entries so this map doesn't grow
+	// without bound on a long-lived leader.
+	for k, t := range s.recentEvents {
+		if now.Sub(t) > userEventTTL {
+			delete(s.recentEvents, k)
+		}
+	}
+	return false
+}
+
+// recordUserEvent appends to the bounded ring buffer backing Internal.EventList.
+func (s *Server) recordUserEvent(params *UserEventParam) {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+
+	if s.eventBuf == nil {
+		s.eventBuf = make([]*UserEvent, maxEventBuffer)
+	}
+	s.eventBuf[s.eventIndex%maxEventBuffer] = &UserEvent{
+		ID:            params.ID,
+		Name:          params.Name,
+		Payload:       params.Payload,
+		NodeFilter:    params.NodeFilter,
+		ServiceFilter: params.ServiceFilter,
+		TagFilter:     params.TagFilter,
+	}
+	s.eventIndex++
+}
+
+// recentUserEvents returns the events currently held in the ring buffer,
+// oldest first.
+func (s *Server) recentUserEvents() []*UserEvent {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+
+	var out []*UserEvent
+	for i := 0; i < len(s.eventBuf); i++ {
+		idx := (s.eventIndex + i) % maxEventBuffer
+		if e := s.eventBuf[idx]; e != nil {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// relayUserEvent forwards an event tagged for other datacenters to each
+// target leader over RPC, so it can be re-fired on that datacenter's own
+// LAN pool. Only the leader does this so every LAN member doesn't
+// independently flood the target datacenter with the same relay.
+func (s *Server) relayUserEvent(params *UserEventParam) {
+	for _, dc := range params.RelayDatacenters {
+		if dc == s.config.Datacenter {
+			continue
+		}
+		go func(dc string) {
+			args := EventFireRequest{
+				Datacenter:    dc,
+				ID:            params.ID,
+				Name:          params.Name,
+				Payload:       params.Payload,
+				NodeFilter:    params.NodeFilter,
+				ServiceFilter: params.ServiceFilter,
+				TagFilter:     params.TagFilter,
+			}
+			var reply EventFireResponse
+			if err := s.forwardDC("Internal.EventFire", dc, &args, &reply); err != nil {
+				s.logger.Printf("[ERR] consul: Failed to relay event %q to dc %q: %v", params.Name, dc, err)
+			}
+		}(dc)
+	}
+}
@@ -0,0 +1,58 @@
+package consul
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEncodeDecodeUserEvent(t *testing.T) {
+	in := &UserEventParam{
+		Version: userEventMaxVersion,
+		ID:      "abc",
+		Name:    "deploy",
+		Payload: []byte("1.2.3"),
+	}
+
+	encoded, err := encodeUserEvent(in)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := decodeUserEvent(encoded)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.ID != in.ID || out.Name != in.Name || string(out.Payload) != string(in.Payload) {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestDecodeUserEvent_UnsupportedVersion(t *testing.T) {
+	encoded, err := encodeUserEvent(&UserEventParam{Version: userEventMaxVersion + 1})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := decodeUserEvent(encoded); err == nil {
+		t.Fatalf("expected error for unsupported event version")
+	}
+}
+
+func TestRecentUserEvents_Ordering(t *testing.T) {
+	dir, s := testServer(t)
+	defer os.RemoveAll(dir)
+	defer s.Shutdown()
+
+	for i := 0; i < 3; i++ {
+		s.recordUserEvent(&UserEventParam{ID: string(rune('a' + i)), Name: "e"})
+	}
+
+	events := s.recentUserEvents()
+	if len(events) != 3 {
+		t.Fatalf("bad: %#v", events)
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if events[i].ID != want {
+			t.Fatalf("bad order, expected oldest-first: %#v", events)
+		}
+	}
+}